@@ -0,0 +1,19 @@
+package config
+
+// Config is the vcluster config. Only the branch leading to HybridScheduling is modeled here;
+// the full config has many more sync options.
+type Config struct {
+	Sync SyncConfig `json:"sync,omitempty"`
+}
+
+type SyncConfig struct {
+	ToHost ToHostSyncConfig `json:"toHost,omitempty"`
+}
+
+type ToHostSyncConfig struct {
+	Pods PodsSyncConfig `json:"pods,omitempty"`
+}
+
+type PodsSyncConfig struct {
+	HybridScheduling HybridSchedulingConfig `json:"hybridScheduling,omitempty"`
+}
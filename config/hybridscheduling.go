@@ -0,0 +1,52 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HybridSchedulingConfig configures hybrid scheduling, i.e. letting some schedulers run on the
+// host cluster while the vcluster scheduler handles the rest.
+type HybridSchedulingConfig struct {
+	// Enabled indicates if hybrid scheduling is enabled.
+	Enabled bool `json:"enabled,omitempty"`
+	// HostSchedulers are the schedulers that are reserved for the host cluster. A pod in the
+	// virtual cluster whose spec.schedulerName matches one of these rules is rejected instead
+	// of being admitted, since only the host cluster is allowed to schedule it.
+	HostSchedulers []HostSchedulerRule `json:"hostSchedulers,omitempty"`
+}
+
+// HostSchedulerRule describes a scheduler (or glob pattern of scheduler names) that is
+// reserved for the host cluster. NamespaceSelector and PodSelector are both optional; when
+// set, the rule only applies to pods whose namespace, respectively pod, labels match.
+type HostSchedulerRule struct {
+	// Name is either an exact scheduler name or a glob pattern (as understood by path.Match),
+	// e.g. "gpu-*".
+	Name string `json:"name,omitempty"`
+	// NamespaceSelector, if set, restricts the rule to pods running in namespaces matching
+	// this selector.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// PodSelector, if set, restricts the rule to pods matching this selector.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+}
+
+// UnmarshalJSON allows a HostSchedulerRule to be written as a plain scheduler name string
+// in addition to the structured object form, so existing `hostSchedulers: ["my-scheduler"]`
+// configs keep working unchanged.
+func (r *HostSchedulerRule) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		r.Name = name
+		return nil
+	}
+
+	type hostSchedulerRule HostSchedulerRule
+	var rule hostSchedulerRule
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return fmt.Errorf("failed to unmarshal host scheduler rule: %w", err)
+	}
+	*r = HostSchedulerRule(rule)
+	return nil
+}
@@ -0,0 +1,69 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/loft-sh/vcluster/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isSchedulerConfiguredAsHostScheduler reports whether pod's scheduler matches one of the
+// configured host scheduler rules. Namespace labels are looked up via virtualClient so that
+// namespaceSelector can be evaluated without requiring the caller to fetch the namespace. On a
+// match, the matched rule is returned alongside so callers can report which rule fired.
+func isSchedulerConfiguredAsHostScheduler(ctx context.Context, virtualClient client.Client, hostSchedulers []config.HostSchedulerRule, pod *corev1.Pod) (bool, config.HostSchedulerRule, error) {
+	var namespace *corev1.Namespace
+
+	for _, rule := range hostSchedulers {
+		if !schedulerNameMatches(rule.Name, pod.Spec.SchedulerName) {
+			continue
+		}
+
+		if rule.PodSelector != nil {
+			podSelector, err := metav1.LabelSelectorAsSelector(rule.PodSelector)
+			if err != nil {
+				return false, config.HostSchedulerRule{}, fmt.Errorf("failed to parse podSelector for host scheduler rule %q: %w", rule.Name, err)
+			}
+			if !podSelector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+		}
+
+		if rule.NamespaceSelector != nil {
+			if namespace == nil {
+				namespace = &corev1.Namespace{}
+				if err := virtualClient.Get(ctx, client.ObjectKey{Name: pod.Namespace}, namespace); err != nil {
+					return false, config.HostSchedulerRule{}, fmt.Errorf("failed to get namespace %s: %w", pod.Namespace, err)
+				}
+			}
+
+			namespaceSelector, err := metav1.LabelSelectorAsSelector(rule.NamespaceSelector)
+			if err != nil {
+				return false, config.HostSchedulerRule{}, fmt.Errorf("failed to parse namespaceSelector for host scheduler rule %q: %w", rule.Name, err)
+			}
+			if !namespaceSelector.Matches(labels.Set(namespace.Labels)) {
+				continue
+			}
+		}
+
+		return true, rule, nil
+	}
+
+	return false, config.HostSchedulerRule{}, nil
+}
+
+// schedulerNameMatches reports whether schedulerName matches pattern, which may be an exact
+// name or a glob pattern understood by path.Match (e.g. "gpu-*").
+func schedulerNameMatches(pattern, schedulerName string) bool {
+	if pattern == schedulerName {
+		return true
+	}
+
+	matched, err := path.Match(pattern, schedulerName)
+	return err == nil && matched
+}
@@ -0,0 +1,140 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/vcluster/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSchedulerNameMatches(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		schedulerName string
+		want          bool
+	}{
+		{name: "exact match", pattern: "gpu-scheduler", schedulerName: "gpu-scheduler", want: true},
+		{name: "exact mismatch", pattern: "gpu-scheduler", schedulerName: "default-scheduler", want: false},
+		{name: "glob match", pattern: "gpu-*", schedulerName: "gpu-scheduler", want: true},
+		{name: "glob mismatch", pattern: "gpu-*", schedulerName: "cpu-scheduler", want: false},
+		{name: "invalid pattern does not match", pattern: "[", schedulerName: "other-scheduler", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schedulerNameMatches(tt.pattern, tt.schedulerName); got != tt.want {
+				t.Errorf("schedulerNameMatches(%q, %q) = %v, want %v", tt.pattern, tt.schedulerName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSchedulerConfiguredAsHostScheduler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add corev1 to scheme: %v", err)
+	}
+
+	gpuNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "gpu-tenant",
+			Labels: map[string]string{"tier": "gpu"},
+		},
+	}
+	otherNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-tenant"},
+	}
+
+	virtualClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gpuNamespace, otherNamespace).Build()
+
+	hostSchedulers := []config.HostSchedulerRule{
+		{Name: "gpu-*"},
+		{
+			Name:              "shared-scheduler",
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gpu"}},
+		},
+		{
+			Name:        "team-scheduler",
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		pod          *corev1.Pod
+		wantRejected bool
+		wantRuleName string
+	}{
+		{
+			name: "glob name match",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "other-tenant"},
+				Spec:       corev1.PodSpec{SchedulerName: "gpu-scheduler"},
+			},
+			wantRejected: true,
+			wantRuleName: "gpu-*",
+		},
+		{
+			name: "namespace selector matches",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "gpu-tenant"},
+				Spec:       corev1.PodSpec{SchedulerName: "shared-scheduler"},
+			},
+			wantRejected: true,
+			wantRuleName: "shared-scheduler",
+		},
+		{
+			name: "namespace selector does not match",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "other-tenant"},
+				Spec:       corev1.PodSpec{SchedulerName: "shared-scheduler"},
+			},
+			wantRejected: false,
+		},
+		{
+			name: "pod selector matches",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "other-tenant", Labels: map[string]string{"team": "payments"}},
+				Spec:       corev1.PodSpec{SchedulerName: "team-scheduler"},
+			},
+			wantRejected: true,
+			wantRuleName: "team-scheduler",
+		},
+		{
+			name: "pod selector does not match",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "other-tenant", Labels: map[string]string{"team": "platform"}},
+				Spec:       corev1.PodSpec{SchedulerName: "team-scheduler"},
+			},
+			wantRejected: false,
+		},
+		{
+			name: "no rule matches",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "other-tenant"},
+				Spec:       corev1.PodSpec{SchedulerName: "default-scheduler"},
+			},
+			wantRejected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rejected, rule, err := isSchedulerConfiguredAsHostScheduler(context.Background(), virtualClient, hostSchedulers, tt.pod)
+			if err != nil {
+				t.Fatalf("isSchedulerConfiguredAsHostScheduler() returned error: %v", err)
+			}
+			if rejected != tt.wantRejected {
+				t.Fatalf("isSchedulerConfiguredAsHostScheduler() rejected = %v, want %v", rejected, tt.wantRejected)
+			}
+			if tt.wantRejected && rule.Name != tt.wantRuleName {
+				t.Fatalf("isSchedulerConfiguredAsHostScheduler() matched rule = %q, want %q", rule.Name, tt.wantRuleName)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package filters
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// hybridSchedulingMetrics holds the hybrid scheduling counters for a single filter instance.
+// They are created against the registerer injected via synccontext.RegisterContext (rather
+// than package-global promauto vars) so tests can supply a fresh prometheus.Registry per filter
+// instance. Constructing the filter twice against the same registerer, including the default
+// controller-runtime registry, panics on duplicate registration.
+type hybridSchedulingMetrics struct {
+	rejectedTotal *prometheus.CounterVec
+	allowedTotal  *prometheus.CounterVec
+}
+
+func newHybridSchedulingMetrics(reg prometheus.Registerer) *hybridSchedulingMetrics {
+	return &hybridSchedulingMetrics{
+		rejectedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "vcluster_hybrid_scheduling_binding_rejected_total",
+			Help: "Number of pod create/server-side-apply/binding requests rejected because they used a scheduler reserved for the host cluster",
+		}, []string{"scheduler", "namespace"}),
+
+		allowedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "vcluster_hybrid_scheduling_binding_allowed_total",
+			Help: "Number of pod create/server-side-apply/binding requests that passed the hybrid scheduling host-scheduler check",
+		}, []string{"namespace"}),
+	}
+}
@@ -1,23 +1,36 @@
 package filters
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"slices"
 
 	"github.com/loft-sh/vcluster/pkg/syncer/synccontext"
 	"github.com/loft-sh/vcluster/pkg/util/encoding"
 	requestpkg "github.com/loft-sh/vcluster/pkg/util/request"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
 	"k8s.io/apiserver/pkg/endpoints/request"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+// schedulerNotAllowedEventReason is the Event reason recorded on a Pod whose scheduler is
+// rejected because it collides with a configured host scheduler.
+const schedulerNotAllowedEventReason = "SchedulerNotAllowed"
+
+// applyPatchContentType is the content type the apiserver assigns RequestInfo.Verb == "patch"
+// for Server-Side Apply. Unlike a JSON Patch or merge-patch body, an apply-patch body is a full
+// (or near-full) object manifest, so it can create a brand-new pod even though the verb is
+// "patch" rather than "create".
+const applyPatchContentType = "application/apply-patch+yaml"
+
 func WithPodSchedulerCheck(h http.Handler, ctx *synccontext.RegisterContext, cachedVirtualClient client.Client) http.Handler {
 	if !ctx.Config.Sync.ToHost.Pods.HybridScheduling.Enabled {
 		return h
@@ -26,6 +39,15 @@ func WithPodSchedulerCheck(h http.Handler, ctx *synccontext.RegisterContext, cac
 	scheme := cachedVirtualClient.Scheme()
 	decoder := encoding.NewDecoder(scheme, false)
 	s := serializer.NewCodecFactory(scheme)
+	recorder := ctx.EventRecorder
+	if recorder == nil {
+		recorder = ctx.VirtualManager.GetEventRecorderFor("hybrid-scheduling")
+	}
+	registerer := ctx.MetricsRegisterer
+	if registerer == nil {
+		registerer = ctrlmetrics.Registry
+	}
+	metrics := newHybridSchedulingMetrics(registerer)
 
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		requestInfo, ok := request.RequestInfoFrom(req.Context())
@@ -33,43 +55,87 @@ func WithPodSchedulerCheck(h http.Handler, ctx *synccontext.RegisterContext, cac
 			requestpkg.FailWithStatus(w, req, http.StatusInternalServerError, fmt.Errorf("request info is missing"))
 			return
 		}
-		if !isCreatePodBindingRequest(requestInfo) {
-			h.ServeHTTP(w, req)
-			return
-		}
 
-		requestBody, err := io.ReadAll(req.Body)
-		if err != nil {
-			responsewriters.ErrorNegotiated(err, s, corev1.SchemeGroupVersion, w, req)
-			return
-		}
-
-		vBinding, err := getBindingResourceFromRequest(requestInfo, requestBody, decoder)
-		if err != nil {
-			responsewriters.ErrorNegotiated(err, s, corev1.SchemeGroupVersion, w, req)
-			return
-		}
-		if vBinding.Namespace == "" || vBinding.Name == "" {
+		var pod *corev1.Pod
+		switch {
+		case isCreatePodBindingRequest(requestInfo):
+			requestBody, err := io.ReadAll(req.Body)
+			if err != nil {
+				responsewriters.ErrorNegotiated(err, s, corev1.SchemeGroupVersion, w, req)
+				return
+			}
+			req.Body = io.NopCloser(bytes.NewReader(requestBody))
+
+			vBinding, err := getBindingResourceFromRequest(requestInfo, requestBody, decoder)
+			if err != nil {
+				responsewriters.ErrorNegotiated(err, s, corev1.SchemeGroupVersion, w, req)
+				return
+			}
+			if vBinding.Namespace == "" || vBinding.Name == "" {
+				h.ServeHTTP(w, req)
+				return
+			}
+
+			pod, err = getPodFromBinding(ctx, ctx.VirtualManager.GetClient(), vBinding)
+			if err != nil {
+				responsewriters.ErrorNegotiated(err, s, corev1.SchemeGroupVersion, w, req)
+				return
+			}
+		case isCreatePodRequest(requestInfo) || isPodApplyPatchRequest(requestInfo, req.Header.Get("Content-Type")):
+			requestBody, err := io.ReadAll(req.Body)
+			if err != nil {
+				responsewriters.ErrorNegotiated(err, s, corev1.SchemeGroupVersion, w, req)
+				return
+			}
+			req.Body = io.NopCloser(bytes.NewReader(requestBody))
+
+			pod, err = getPodFromRequest(requestBody, decoder)
+			if err != nil {
+				responsewriters.ErrorNegotiated(err, s, corev1.SchemeGroupVersion, w, req)
+				return
+			}
+			// The pod body does not necessarily carry metadata.namespace (clients routinely
+			// omit it on create), so fall back to the namespace carried in the request URL.
+			if pod.Namespace == "" {
+				pod.Namespace = requestInfo.Namespace
+			}
+		default:
 			h.ServeHTTP(w, req)
 			return
 		}
 
-		pod, err := getPodFromBinding(ctx, ctx.VirtualManager.GetClient(), vBinding)
+		rejected, matchedRule, err := isSchedulerConfiguredAsHostScheduler(req.Context(), ctx.VirtualManager.GetClient(), ctx.Config.Sync.ToHost.Pods.HybridScheduling.HostSchedulers, pod)
 		if err != nil {
 			responsewriters.ErrorNegotiated(err, s, corev1.SchemeGroupVersion, w, req)
 			return
 		}
-
-		if isSchedulerConfiguredAsHostScheduler(ctx.Config.Sync.ToHost.Pods.HybridScheduling.HostSchedulers, pod.Spec.SchedulerName) {
-			err = fmt.Errorf("scheduler %s is configured as a host scheduler, so a scheduler with the same name is not allowed to schedule the pods in the virtual cluster", pod.Spec.SchedulerName)
-			requestpkg.FailWithStatus(w, req, http.StatusMethodNotAllowed, err)
+		if rejected {
+			// Label by the matched rule name rather than the attacker-controlled
+			// pod.Spec.SchedulerName, which would otherwise be an unbounded-cardinality label.
+			metrics.rejectedTotal.WithLabelValues(matchedRule.Name, pod.Namespace).Inc()
+			recorder.Eventf(pod, corev1.EventTypeWarning, schedulerNotAllowedEventReason,
+				"scheduler %s is not allowed to schedule pods in this namespace, it matches host scheduler rule %q", pod.Spec.SchedulerName, matchedRule.Name)
+			responsewriters.ErrorNegotiated(schedulerNotAllowedError(pod.Spec.SchedulerName), s, corev1.SchemeGroupVersion, w, req)
 			return
 		}
+		metrics.allowedTotal.WithLabelValues(pod.Namespace).Inc()
 
 		h.ServeHTTP(w, req)
 	})
 }
 
+// schedulerNotAllowedError builds the 405 returned for both the pods/binding and the
+// direct pod write paths, so callers see an identical error body regardless of which
+// one rejected the request.
+func schedulerNotAllowedError(schedulerName string) error {
+	return &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Message: fmt.Sprintf("scheduler %s is configured as a host scheduler, so a scheduler with the same name is not allowed to schedule the pods in the virtual cluster", schedulerName),
+		Reason:  metav1.StatusReasonMethodNotAllowed,
+		Code:    http.StatusMethodNotAllowed,
+	}}
+}
+
 func isCreatePodBindingRequest(r *request.RequestInfo) bool {
 	if !r.IsResourceRequest {
 		return false
@@ -82,6 +148,42 @@ func isCreatePodBindingRequest(r *request.RequestInfo) bool {
 		r.Verb == "create"
 }
 
+// isCreatePodRequest reports whether the request creates a pod directly, i.e. POST pods, as
+// opposed to the pods/binding subresource handled by isCreatePodBindingRequest. Update/patch
+// requests are deliberately not intercepted here: spec.schedulerName is immutable after
+// create, and a JSON Patch or merge-patch body is not a full Pod, so decoding it as one would
+// either reject unrelated pod updates outright or silently no-op. Server-Side Apply creates are
+// handled separately by isPodApplyPatchRequest, since those arrive as Verb == "patch" too but
+// carry a full manifest.
+func isCreatePodRequest(r *request.RequestInfo) bool {
+	if !r.IsResourceRequest {
+		return false
+	}
+
+	return r.APIGroup == corev1.SchemeGroupVersion.Group &&
+		r.APIVersion == corev1.SchemeGroupVersion.Version &&
+		r.Resource == "pods" &&
+		r.Subresource == "" &&
+		r.Verb == "create"
+}
+
+// isPodApplyPatchRequest reports whether the request is a Server-Side Apply write to the pods
+// resource. SSA apply-create (e.g. `kubectl apply --server-side`) is submitted as a PATCH with
+// RequestInfo.Verb == "patch" and Content-Type application/apply-patch+yaml, even when it is
+// creating a brand-new pod, so it must be decoded and checked the same way as a direct create.
+func isPodApplyPatchRequest(r *request.RequestInfo, contentType string) bool {
+	if !r.IsResourceRequest {
+		return false
+	}
+
+	return r.APIGroup == corev1.SchemeGroupVersion.Group &&
+		r.APIVersion == corev1.SchemeGroupVersion.Version &&
+		r.Resource == "pods" &&
+		r.Subresource == "" &&
+		r.Verb == "patch" &&
+		contentType == applyPatchContentType
+}
+
 func getBindingResourceFromRequest(requestInfo *request.RequestInfo, requestBody []byte, decoder encoding.Decoder) (*corev1.Binding, error) {
 	if requestInfo == nil {
 		return nil, errors.New("requestInfo is nil")
@@ -104,6 +206,25 @@ func getBindingResourceFromRequest(requestInfo *request.RequestInfo, requestBody
 	return vBinding, nil
 }
 
+func getPodFromRequest(requestBody []byte, decoder encoding.Decoder) (*corev1.Pod, error) {
+	if decoder == nil {
+		return nil, errors.New("decoder is nil")
+	}
+
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+	vObject, err := decoder.Decode(requestBody, &podGVK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Pod resource from request body: %w", err)
+	}
+
+	pod, ok := vObject.(*corev1.Pod)
+	if !ok {
+		return nil, fmt.Errorf("expected pod object")
+	}
+
+	return pod, nil
+}
+
 func getPodFromBinding(ctx context.Context, cachedVirtualClient client.Client, binding *corev1.Binding) (*corev1.Pod, error) {
 	namespacedName := client.ObjectKey{
 		Namespace: binding.Namespace,
@@ -116,7 +237,3 @@ func getPodFromBinding(ctx context.Context, cachedVirtualClient client.Client, b
 	}
 	return pod, nil
 }
-
-func isSchedulerConfiguredAsHostScheduler(hostSchedulers []string, schedulerName string) bool {
-	return slices.Contains(hostSchedulers, schedulerName)
-}
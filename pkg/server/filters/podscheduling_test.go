@@ -0,0 +1,402 @@
+package filters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/loft-sh/vcluster/config"
+	"github.com/loft-sh/vcluster/pkg/syncer/synccontext"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+func TestIsCreatePodBindingRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		info *request.RequestInfo
+		want bool
+	}{
+		{
+			name: "pods binding create",
+			info: &request.RequestInfo{IsResourceRequest: true, APIGroup: "", APIVersion: "v1", Resource: "pods", Subresource: "binding", Verb: "create"},
+			want: true,
+		},
+		{
+			name: "pods create is not a binding",
+			info: &request.RequestInfo{IsResourceRequest: true, APIGroup: "", APIVersion: "v1", Resource: "pods", Subresource: "", Verb: "create"},
+			want: false,
+		},
+		{
+			name: "not a resource request",
+			info: &request.RequestInfo{IsResourceRequest: false},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCreatePodBindingRequest(tt.info); got != tt.want {
+				t.Errorf("isCreatePodBindingRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCreatePodRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		info *request.RequestInfo
+		want bool
+	}{
+		{
+			name: "pods create",
+			info: &request.RequestInfo{IsResourceRequest: true, APIGroup: "", APIVersion: "v1", Resource: "pods", Subresource: "", Verb: "create"},
+			want: true,
+		},
+		{
+			name: "pods update is not intercepted",
+			info: &request.RequestInfo{IsResourceRequest: true, APIGroup: "", APIVersion: "v1", Resource: "pods", Subresource: "", Verb: "update"},
+			want: false,
+		},
+		{
+			name: "pods patch is not intercepted",
+			info: &request.RequestInfo{IsResourceRequest: true, APIGroup: "", APIVersion: "v1", Resource: "pods", Subresource: "", Verb: "patch"},
+			want: false,
+		},
+		{
+			name: "pods binding create is not a direct create",
+			info: &request.RequestInfo{IsResourceRequest: true, APIGroup: "", APIVersion: "v1", Resource: "pods", Subresource: "binding", Verb: "create"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCreatePodRequest(tt.info); got != tt.want {
+				t.Errorf("isCreatePodRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerNotAllowedError(t *testing.T) {
+	err := schedulerNotAllowedError("gpu-scheduler")
+
+	status, ok := err.(interface{ Status() metav1.Status })
+	if !ok {
+		t.Fatalf("schedulerNotAllowedError() does not implement apierrors.APIStatus")
+	}
+
+	if status.Status().Code != http.StatusMethodNotAllowed {
+		t.Errorf("schedulerNotAllowedError() code = %d, want %d", status.Status().Code, http.StatusMethodNotAllowed)
+	}
+	if status.Status().Reason != metav1.StatusReasonMethodNotAllowed {
+		t.Errorf("schedulerNotAllowedError() reason = %q, want %q", status.Status().Reason, metav1.StatusReasonMethodNotAllowed)
+	}
+}
+
+// fakeManager implements just enough of manager.Manager for WithPodSchedulerCheck, which only
+// calls GetClient(). Any other method call panics on the embedded nil interface, which is fine
+// since the filter under test never reaches them.
+type fakeManager struct {
+	manager.Manager
+	client client.Client
+}
+
+func (m *fakeManager) GetClient() client.Client { return m.client }
+
+func newTestRegisterContext(t *testing.T, hostSchedulers []config.HostSchedulerRule, objects ...client.Object) (*synccontext.RegisterContext, *record.FakeRecorder, *prometheus.Registry) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add corev1 to scheme: %v", err)
+	}
+
+	virtualClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	recorder := record.NewFakeRecorder(10)
+	registry := prometheus.NewRegistry()
+
+	cfg := &config.Config{}
+	cfg.Sync.ToHost.Pods.HybridScheduling.Enabled = true
+	cfg.Sync.ToHost.Pods.HybridScheduling.HostSchedulers = hostSchedulers
+
+	ctx := &synccontext.RegisterContext{
+		Context:           context.Background(),
+		Config:            cfg,
+		VirtualManager:    &fakeManager{client: virtualClient},
+		EventRecorder:     recorder,
+		MetricsRegisterer: registry,
+	}
+
+	return ctx, recorder, registry
+}
+
+func passThroughHandler() (http.Handler, *[]byte) {
+	var receivedBody []byte
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	})
+	return handler, &receivedBody
+}
+
+func newPodRequest(t *testing.T, verb, namespace string, pod *corev1.Pod) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("failed to marshal pod: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/"+namespace+"/pods", bytes.NewReader(body))
+	info := &request.RequestInfo{
+		IsResourceRequest: true,
+		APIVersion:        "v1",
+		Resource:          "pods",
+		Verb:              verb,
+		Namespace:         namespace,
+	}
+	return req.WithContext(request.WithRequestInfo(req.Context(), info))
+}
+
+func newApplyPatchPodRequest(t *testing.T, namespace, name string, pod *corev1.Pod) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("failed to marshal pod: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/namespaces/"+namespace+"/pods/"+name, bytes.NewReader(body))
+	req.Header.Set("Content-Type", applyPatchContentType)
+	info := &request.RequestInfo{
+		IsResourceRequest: true,
+		APIVersion:        "v1",
+		Resource:          "pods",
+		Verb:              "patch",
+		Namespace:         namespace,
+		Name:              name,
+	}
+	return req.WithContext(request.WithRequestInfo(req.Context(), info))
+}
+
+func newBindingRequest(t *testing.T, namespace string, binding *corev1.Binding) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(binding)
+	if err != nil {
+		t.Fatalf("failed to marshal binding: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/"+namespace+"/pods/"+binding.Name+"/binding", bytes.NewReader(body))
+	info := &request.RequestInfo{
+		IsResourceRequest: true,
+		APIVersion:        "v1",
+		Resource:          "pods",
+		Subresource:       "binding",
+		Verb:              "create",
+		Namespace:         namespace,
+	}
+	return req.WithContext(request.WithRequestInfo(req.Context(), info))
+}
+
+func TestWithPodSchedulerCheck_RejectsHostSchedulerOnCreate(t *testing.T) {
+	ctx, recorder, registry := newTestRegisterContext(t, []config.HostSchedulerRule{{Name: "gpu-scheduler"}})
+	handler, _ := passThroughHandler()
+
+	// Namespace deliberately omitted from the pod body: it must come from requestInfo.Namespace.
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pod"},
+		Spec:       corev1.PodSpec{SchedulerName: "gpu-scheduler"},
+	}
+	req := newPodRequest(t, "create", "my-ns", pod)
+
+	w := httptest.NewRecorder()
+	WithPodSchedulerCheck(handler, ctx, ctx.VirtualManager.GetClient()).ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d; body = %s", w.Code, http.StatusMethodNotAllowed, w.Body.String())
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !bytes.Contains([]byte(event), []byte(schedulerNotAllowedEventReason)) {
+			t.Errorf("event = %q, want it to contain reason %q", event, schedulerNotAllowedEventReason)
+		}
+	default:
+		t.Errorf("expected a SchedulerNotAllowed event to be recorded")
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	if !metricFamilyHasSample(families, "vcluster_hybrid_scheduling_binding_rejected_total", map[string]string{"scheduler": "gpu-scheduler", "namespace": "my-ns"}) {
+		t.Errorf("expected a rejected_total sample for scheduler=gpu-scheduler,namespace=my-ns, got families: %+v", families)
+	}
+}
+
+func TestWithPodSchedulerCheck_AllowsOtherSchedulerOnCreate(t *testing.T) {
+	ctx, _, registry := newTestRegisterContext(t, []config.HostSchedulerRule{{Name: "gpu-scheduler"}})
+	handler, receivedBody := passThroughHandler()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pod", Namespace: "my-ns"},
+		Spec:       corev1.PodSpec{SchedulerName: "default-scheduler"},
+	}
+	req := newPodRequest(t, "create", "my-ns", pod)
+
+	w := httptest.NewRecorder()
+	WithPodSchedulerCheck(handler, ctx, ctx.VirtualManager.GetClient()).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body = %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var forwarded corev1.Pod
+	if err := json.Unmarshal(*receivedBody, &forwarded); err != nil {
+		t.Fatalf("downstream handler did not receive the original (restored) body: %v", err)
+	}
+	if forwarded.Spec.SchedulerName != "default-scheduler" {
+		t.Errorf("forwarded pod scheduler = %q, want %q", forwarded.Spec.SchedulerName, "default-scheduler")
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	if !metricFamilyHasSample(families, "vcluster_hybrid_scheduling_binding_allowed_total", map[string]string{"namespace": "my-ns"}) {
+		t.Errorf("expected an allowed_total sample for namespace=my-ns, got families: %+v", families)
+	}
+}
+
+func TestWithPodSchedulerCheck_PassesThroughUpdateAndPatch(t *testing.T) {
+	for _, verb := range []string{"update", "patch"} {
+		t.Run(verb, func(t *testing.T) {
+			ctx, _, _ := newTestRegisterContext(t, []config.HostSchedulerRule{{Name: "gpu-scheduler"}})
+			handler, _ := passThroughHandler()
+
+			// A JSON Patch body; would fail to decode as a corev1.Pod if this path were
+			// (incorrectly) intercepted.
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/namespaces/my-ns/pods/my-pod", bytes.NewReader([]byte(`[{"op":"replace","path":"/metadata/labels","value":{}}]`)))
+			info := &request.RequestInfo{IsResourceRequest: true, APIVersion: "v1", Resource: "pods", Verb: verb, Namespace: "my-ns", Name: "my-pod"}
+			req = req.WithContext(request.WithRequestInfo(req.Context(), info))
+
+			w := httptest.NewRecorder()
+			WithPodSchedulerCheck(handler, ctx, ctx.VirtualManager.GetClient()).ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d; body = %s", w.Code, http.StatusOK, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWithPodSchedulerCheck_RejectsHostSchedulerOnApplyCreate(t *testing.T) {
+	ctx, _, _ := newTestRegisterContext(t, []config.HostSchedulerRule{{Name: "gpu-scheduler"}})
+	handler, _ := passThroughHandler()
+
+	// `kubectl apply --server-side` on a pod name that does not yet exist: RequestInfo.Verb is
+	// "patch", not "create", but the body is a full manifest rather than a JSON Patch/merge-patch.
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pod", Namespace: "my-ns"},
+		Spec:       corev1.PodSpec{SchedulerName: "gpu-scheduler"},
+	}
+	req := newApplyPatchPodRequest(t, "my-ns", "my-pod", pod)
+
+	w := httptest.NewRecorder()
+	WithPodSchedulerCheck(handler, ctx, ctx.VirtualManager.GetClient()).ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d; body = %s", w.Code, http.StatusMethodNotAllowed, w.Body.String())
+	}
+}
+
+func TestWithPodSchedulerCheck_RejectsHostSchedulerOnBinding(t *testing.T) {
+	existingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pod", Namespace: "my-ns"},
+		Spec:       corev1.PodSpec{SchedulerName: "gpu-scheduler"},
+	}
+	ctx, _, _ := newTestRegisterContext(t, []config.HostSchedulerRule{{Name: "gpu-scheduler"}}, existingPod)
+	handler, _ := passThroughHandler()
+
+	binding := &corev1.Binding{ObjectMeta: metav1.ObjectMeta{Name: "my-pod", Namespace: "my-ns"}}
+	req := newBindingRequest(t, "my-ns", binding)
+
+	w := httptest.NewRecorder()
+	WithPodSchedulerCheck(handler, ctx, ctx.VirtualManager.GetClient()).ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d; body = %s", w.Code, http.StatusMethodNotAllowed, w.Body.String())
+	}
+}
+
+func TestWithPodSchedulerCheck_CreateAndBindingProduceIdenticalErrorBody(t *testing.T) {
+	existingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pod", Namespace: "my-ns"},
+		Spec:       corev1.PodSpec{SchedulerName: "gpu-scheduler"},
+	}
+	hostSchedulers := []config.HostSchedulerRule{{Name: "gpu-scheduler"}}
+	handler, _ := passThroughHandler()
+
+	createCtx, _, _ := newTestRegisterContext(t, hostSchedulers)
+	createReq := newPodRequest(t, "create", "my-ns", &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-pod", Namespace: "my-ns"},
+		Spec:       corev1.PodSpec{SchedulerName: "gpu-scheduler"},
+	})
+	createW := httptest.NewRecorder()
+	WithPodSchedulerCheck(handler, createCtx, createCtx.VirtualManager.GetClient()).ServeHTTP(createW, createReq)
+
+	bindingCtx, _, _ := newTestRegisterContext(t, hostSchedulers, existingPod)
+	bindingReq := newBindingRequest(t, "my-ns", &corev1.Binding{ObjectMeta: metav1.ObjectMeta{Name: "my-pod", Namespace: "my-ns"}})
+	bindingW := httptest.NewRecorder()
+	WithPodSchedulerCheck(handler, bindingCtx, bindingCtx.VirtualManager.GetClient()).ServeHTTP(bindingW, bindingReq)
+
+	if createW.Code != bindingW.Code {
+		t.Fatalf("status codes differ: create=%d binding=%d", createW.Code, bindingW.Code)
+	}
+	if createW.Body.String() != bindingW.Body.String() {
+		t.Fatalf("error bodies differ:\ncreate:  %s\nbinding: %s", createW.Body.String(), bindingW.Body.String())
+	}
+}
+
+func metricFamilyHasSample(families []*dto.MetricFamily, name string, labels map[string]string) bool {
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if sampleMatchesLabels(metric, labels) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sampleMatchesLabels(metric *dto.Metric, labels map[string]string) bool {
+	got := map[string]string{}
+	for _, pair := range metric.GetLabel() {
+		got[pair.GetName()] = pair.GetValue()
+	}
+	for k, v := range labels {
+		if got[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package synccontext
+
+import (
+	"context"
+
+	"github.com/loft-sh/vcluster/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// RegisterContext carries the dependencies needed to register syncers and admission filters.
+// Only the fields used by the server filters are modeled here; the full context carries
+// considerably more (physical manager, mappers, etc.).
+type RegisterContext struct {
+	context.Context
+
+	Config *config.Config
+
+	VirtualManager manager.Manager
+
+	// EventRecorder is used to record Kubernetes events against virtual objects. Defaults to
+	// VirtualManager.GetEventRecorderFor(...) in production, but can be swapped for a fake in
+	// tests.
+	EventRecorder record.EventRecorder
+
+	// MetricsRegisterer is the Prometheus registerer new metrics are registered against.
+	// Defaults to the controller-runtime metrics registry in production, but can be swapped
+	// for a fresh prometheus.NewRegistry() in tests to avoid duplicate-registration panics.
+	MetricsRegisterer prometheus.Registerer
+}